@@ -0,0 +1,276 @@
+package request
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WithBasicAuth sets the Authorization header to HTTP Basic credentials for username and password.
+func WithBasicAuth(username, password string) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+}
+
+// WithBearerToken sets the Authorization header to a Bearer token.
+func WithBearerToken(token string) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.Headers["Authorization"] = "Bearer " + token
+	}
+}
+
+// digestAuth carries the credentials configured via WithDigestAuth.
+type digestAuth struct {
+	username string
+	password string
+}
+
+// digestChallenge is a cached RFC 7616 challenge for one scheme+host pair, along with
+// the nonce-count counter that must strictly increase on every reuse of its nonce.
+type digestChallenge struct {
+	mu     sync.Mutex
+	params map[string]string
+	nc     uint32
+}
+
+// WithDigestAuth configures RFC 7616 HTTP Digest authentication. If the Client has
+// already seen a challenge for this host, it is applied up front; otherwise the request
+// is sent as-is, and if the server responds 401 with a WWW-Authenticate: Digest header,
+// it is transparently reissued with a computed Authorization header. The reissue does
+// not count as a retry attempt. The challenge is cached on the Client keyed by
+// scheme+host so later calls skip the initial 401 round trip.
+func WithDigestAuth(username, password string) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.digestAuth = &digestAuth{username: username, password: password}
+	}
+}
+
+// digestCacheKey identifies a Client's cached challenge by scheme and host.
+func digestCacheKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// digestEntry returns the cache entry for key, creating it on first use.
+func digestEntry(client *Client, key string) *digestChallenge {
+	actual, _ := client.digestChallenges.LoadOrStore(key, &digestChallenge{})
+	return actual.(*digestChallenge)
+}
+
+// applyCachedDigestAuth sets an Authorization header from a previously cached challenge
+// for request's host, if any, so the request has a chance of succeeding without first
+// taking a 401 round trip.
+func applyCachedDigestAuth(client *Client, request *http.Request, auth *digestAuth) {
+	entry := digestEntry(client, digestCacheKey(request.URL))
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.params == nil {
+		return
+	}
+
+	entry.nc++
+	header, err := buildDigestAuthorization(auth, request.Method, request.URL.RequestURI(), entry.params, entry.nc)
+	if err != nil {
+		return
+	}
+	request.Header.Set("Authorization", header)
+}
+
+// isDigestChallenge reports whether resp is a 401 carrying a WWW-Authenticate: Digest challenge.
+func isDigestChallenge(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	_, ok := parseWWWAuthenticateDigest(resp.Header.Get("WWW-Authenticate"))
+	return ok
+}
+
+// reissueWithDigest parses the WWW-Authenticate challenge, caches it on client for reuse,
+// and resends an equivalent request carrying a computed Authorization header.
+func reissueWithDigest(ctx context.Context, httpClient *http.Client, client *Client, method Method, url string, requestConfig *RequestConfiguration, challengeHeader string) (*http.Response, error) {
+	params, ok := parseWWWAuthenticateDigest(challengeHeader)
+	if !ok {
+		return nil, fmt.Errorf("missing WWW-Authenticate: Digest challenge")
+	}
+
+	if requestConfig.BodyReader != nil {
+		seeker, ok := requestConfig.BodyReader.(io.Seeker)
+		if !ok {
+			return nil, fmt.Errorf("request body cannot be replayed for the digest-authenticated reissue")
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind request body for the digest-authenticated reissue: %w", err)
+		}
+	}
+
+	request, err := newRequest(ctx, method, url, requestConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := digestEntry(client, digestCacheKey(request.URL))
+	entry.mu.Lock()
+	entry.params = params
+	entry.nc = 1
+	nc := entry.nc
+	entry.mu.Unlock()
+
+	header, err := buildDigestAuthorization(requestConfig.digestAuth, request.Method, request.URL.RequestURI(), params, nc)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", header)
+
+	return httpClient.Do(request)
+}
+
+// parseWWWAuthenticateDigest parses a "Digest k=v, k=v, ..." challenge header into its
+// parameters. It reports false if header does not carry a Digest challenge.
+func parseWWWAuthenticateDigest(header string) (map[string]string, bool) {
+	const prefix = "Digest "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for _, part := range splitDigestParams(header[len(prefix):]) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		params[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return params, true
+}
+
+// splitDigestParams splits a digest parameter list on commas, ignoring commas enclosed in quotes.
+func splitDigestParams(s string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case r == ',' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	parts = append(parts, current.String())
+
+	return parts
+}
+
+// digestHashFunc returns the hash function named by a WWW-Authenticate algorithm param,
+// defaulting to MD5 for an empty value and treating any "-sess" suffix as already handled
+// by the caller.
+func digestHashFunc(algorithm string) func(string) string {
+	if strings.HasPrefix(strings.ToUpper(algorithm), "SHA-256") {
+		return func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+	}
+	return func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// digestQop picks the qop directive to use from a possibly comma-separated list,
+// preferring "auth" when offered.
+func digestQop(qop string) string {
+	var first string
+	for _, q := range strings.Split(qop, ",") {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			continue
+		}
+		if q == "auth" {
+			return "auth"
+		}
+		if first == "" {
+			first = q
+		}
+	}
+	return first
+}
+
+// buildDigestAuthorization computes an RFC 7616 Authorization header for a request,
+// using a fresh client nonce and the given server nonce count.
+func buildDigestAuthorization(auth *digestAuth, method, uri string, params map[string]string, nc uint32) (string, error) {
+	realm := params["realm"]
+	nonce := params["nonce"]
+	opaque := params["opaque"]
+	algorithm := params["algorithm"]
+	qop := digestQop(params["qop"])
+
+	hash := digestHashFunc(algorithm)
+
+	cnonce, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	ha1 := hash(fmt.Sprintf("%s:%s:%s", auth.username, realm, auth.password))
+	if strings.HasSuffix(strings.ToLower(algorithm), "-sess") {
+		ha1 = hash(fmt.Sprintf("%s:%s:%s", ha1, nonce, cnonce))
+	}
+
+	ha2 := hash(fmt.Sprintf("%s:%s", method, uri))
+
+	ncValue := fmt.Sprintf("%08x", nc)
+
+	var response string
+	if qop != "" {
+		response = hash(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, nonce, ncValue, cnonce, qop, ha2))
+	} else {
+		response = hash(fmt.Sprintf("%s:%s:%s", ha1, nonce, ha2))
+	}
+
+	var header strings.Builder
+	fmt.Fprintf(&header, `Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		auth.username, realm, nonce, uri, response)
+	if opaque != "" {
+		fmt.Fprintf(&header, `, opaque="%s"`, opaque)
+	}
+	if qop != "" {
+		fmt.Fprintf(&header, `, qop=%s, nc=%s, cnonce="%s"`, qop, ncValue, cnonce)
+	}
+	if algorithm != "" {
+		fmt.Fprintf(&header, `, algorithm=%s`, algorithm)
+	}
+
+	return header.String(), nil
+}
+
+// randomHex returns a random hex-encoded string built from n random bytes, as used for
+// the client nonce (cnonce) in digest authentication.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithBasicAuth(t *testing.T) {
+	requestConfig := &RequestConfiguration{Headers: map[string]string{}}
+	WithBasicAuth("user", "pass")(requestConfig)
+
+	if got, want := requestConfig.Headers["Authorization"], "Basic dXNlcjpwYXNz"; got != want {
+		t.Errorf("expected Authorization header %q, got %q", want, got)
+	}
+}
+
+func TestWithBearerToken(t *testing.T) {
+	requestConfig := &RequestConfiguration{Headers: map[string]string{}}
+	WithBearerToken("token123")(requestConfig)
+
+	if got, want := requestConfig.Headers["Authorization"], "Bearer token123"; got != want {
+		t.Errorf("expected Authorization header %q, got %q", want, got)
+	}
+}
+
+func TestParseWWWAuthenticateDigest(t *testing.T) {
+	header := `Digest realm="test@example.com", qop="auth", nonce="abc123", opaque="xyz", algorithm=MD5`
+
+	params, ok := parseWWWAuthenticateDigest(header)
+	if !ok {
+		t.Fatal("expected a Digest challenge to be recognized")
+	}
+
+	for key, want := range map[string]string{
+		"realm":     "test@example.com",
+		"qop":       "auth",
+		"nonce":     "abc123",
+		"opaque":    "xyz",
+		"algorithm": "MD5",
+	} {
+		if params[key] != want {
+			t.Errorf("expected %s=%q, got %q", key, want, params[key])
+		}
+	}
+}
+
+func TestBuildDigestAuthorization(t *testing.T) {
+	auth := &digestAuth{username: "Mufasa", password: "Circle Of Life"}
+	params := map[string]string{
+		"realm": "testrealm@host.com",
+		"nonce": "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+		"qop":   "auth",
+	}
+
+	header, err := buildDigestAuthorization(auth, http.MethodGet, "/dir/index.html", params, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{`username="Mufasa"`, `realm="testrealm@host.com"`, `nc=00000001`, `qop=auth`} {
+		if !strings.Contains(header, want) {
+			t.Errorf("expected digest header to contain %q, got %s", want, header)
+		}
+	}
+}
+
+func TestReissueWithDigest_RejectsUnreplayableBody(t *testing.T) {
+	pipeReader, pipeWriter := io.Pipe()
+	pipeWriter.Close()
+
+	requestConfig := &RequestConfiguration{
+		Headers:    map[string]string{},
+		BodyReader: pipeReader,
+		digestAuth: &digestAuth{username: "user", password: "pass"},
+	}
+
+	_, err := reissueWithDigest(context.Background(), http.DefaultClient, New("https://example.com"), getMethod,
+		"https://example.com/", requestConfig, `Digest realm="r", nonce="n"`)
+	if err == nil {
+		t.Fatal("expected an error for a non-seekable body, got nil")
+	}
+}
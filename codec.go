@@ -0,0 +1,178 @@
+package request
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Encoder turns a value into a request body and names the Content-Type it produces.
+type Encoder interface {
+	ContentType() string
+	Encode(v any) ([]byte, error)
+}
+
+// Decoder turns a response body back into a value and names the Content-Type it handles.
+type Decoder interface {
+	ContentType() string
+	Decode(data []byte, v any) error
+}
+
+// JSONCodec encodes and decodes application/json bodies. It is the default codec used
+// when neither WithEncoder/WithDecoder nor a response Content-Type says otherwise.
+type JSONCodec struct{}
+
+// ContentType returns "application/json".
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// Encode marshals v to JSON.
+func (JSONCodec) Encode(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Decode unmarshals JSON data into v.
+func (JSONCodec) Decode(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// XMLCodec encodes and decodes application/xml bodies.
+type XMLCodec struct{}
+
+// ContentType returns "application/xml".
+func (XMLCodec) ContentType() string { return "application/xml" }
+
+// Encode marshals v to XML.
+func (XMLCodec) Encode(v any) ([]byte, error) { return xml.Marshal(v) }
+
+// Decode unmarshals XML data into v.
+func (XMLCodec) Decode(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// FormCodec encodes and decodes application/x-www-form-urlencoded bodies backed by
+// map[string]string; Encode expects that type and Decode expects *map[string]string.
+type FormCodec struct{}
+
+// ContentType returns "application/x-www-form-urlencoded".
+func (FormCodec) ContentType() string { return "application/x-www-form-urlencoded" }
+
+// Encode url-encodes a map[string]string.
+func (FormCodec) Encode(v any) ([]byte, error) {
+	fields, ok := v.(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("form codec: expected map[string]string, got %T", v)
+	}
+
+	values := url.Values{}
+	for key, value := range fields {
+		values.Set(key, value)
+	}
+
+	return []byte(values.Encode()), nil
+}
+
+// Decode parses a url-encoded body into a *map[string]string.
+func (FormCodec) Decode(data []byte, v any) error {
+	target, ok := v.(*map[string]string)
+	if !ok {
+		return fmt.Errorf("form codec: expected *map[string]string, got %T", v)
+	}
+
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	result := make(map[string]string, len(values))
+	for key := range values {
+		result[key] = values.Get(key)
+	}
+	*target = result
+
+	return nil
+}
+
+// RawCodec passes the body through untouched; Encode expects []byte and Decode expects
+// *[]byte, so it is typically paired with T = []byte.
+type RawCodec struct{}
+
+// ContentType returns "application/octet-stream".
+func (RawCodec) ContentType() string { return "application/octet-stream" }
+
+// Encode returns v as-is, requiring it to already be []byte.
+func (RawCodec) Encode(v any) ([]byte, error) {
+	data, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: expected []byte, got %T", v)
+	}
+	return data, nil
+}
+
+// Decode copies data into a *[]byte untouched.
+func (RawCodec) Decode(data []byte, v any) error {
+	target, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec: expected *[]byte, got %T", v)
+	}
+	*target = data
+	return nil
+}
+
+// decoderForContentType picks a built-in Decoder based on a response's Content-Type
+// header, defaulting to JSONCodec when the type is unrecognized or absent.
+func decoderForContentType(contentType string) Decoder {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "application/xml", "text/xml":
+		return XMLCodec{}
+	case "application/x-www-form-urlencoded":
+		return FormCodec{}
+	case "application/octet-stream":
+		return RawCodec{}
+	default:
+		return JSONCodec{}
+	}
+}
+
+// WithEncoder pins the Encoder used by WithBody to encode the request body, instead of
+// the default JSONCodec. List it before WithBody so the pinned encoder takes effect.
+func WithEncoder(encoder Encoder) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.Encoder = encoder
+	}
+}
+
+// WithDecoder pins the Decoder used to decode the response body, instead of selecting
+// one from the response Content-Type.
+func WithDecoder(decoder Decoder) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.Decoder = decoder
+	}
+}
+
+// WithFormBody encodes fields as application/x-www-form-urlencoded and sets it as the
+// request body.
+func WithFormBody(fields map[string]string) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		codec := FormCodec{}
+		data, err := codec.Encode(fields)
+		if err != nil {
+			return
+		}
+
+		r.Body = data
+		r.Encoder = codec
+		r.Headers["Content-Type"] = codec.ContentType()
+	}
+}
+
+// WithXMLBody marshals body as XML and sets it as the request body.
+func WithXMLBody[T any](body T) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		codec := XMLCodec{}
+		data, err := codec.Encode(body)
+		if err != nil {
+			return
+		}
+
+		r.Body = data
+		r.Encoder = codec
+		r.Headers["Content-Type"] = codec.ContentType()
+	}
+}
@@ -0,0 +1,76 @@
+package request
+
+import "testing"
+
+func TestFormCodec(t *testing.T) {
+	codec := FormCodec{}
+
+	data, err := codec.Encode(map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	var decoded map[string]string
+	if err := codec.Decode(data, &decoded); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if decoded["key"] != "value" {
+		t.Errorf("expected key to be 'value', got %s", decoded["key"])
+	}
+}
+
+func TestXMLCodec(t *testing.T) {
+	type payload struct {
+		Name string `xml:"name"`
+	}
+	codec := XMLCodec{}
+
+	data, err := codec.Encode(payload{Name: "gopher"})
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	var decoded payload
+	if err := codec.Decode(data, &decoded); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if decoded.Name != "gopher" {
+		t.Errorf("expected name to be 'gopher', got %s", decoded.Name)
+	}
+}
+
+func TestRawCodec(t *testing.T) {
+	codec := RawCodec{}
+
+	data, err := codec.Encode([]byte("hello"))
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	var decoded []byte
+	if err := codec.Decode(data, &decoded); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	if string(decoded) != "hello" {
+		t.Errorf("expected 'hello', got %s", decoded)
+	}
+}
+
+func TestDecoderForContentType(t *testing.T) {
+	cases := map[string]Decoder{
+		"application/json; charset=utf-8":   JSONCodec{},
+		"application/xml":                   XMLCodec{},
+		"application/x-www-form-urlencoded": FormCodec{},
+		"application/octet-stream":          RawCodec{},
+		"":                                  JSONCodec{},
+	}
+
+	for contentType, want := range cases {
+		if got := decoderForContentType(contentType); got.ContentType() != want.ContentType() {
+			t.Errorf("decoderForContentType(%q) = %T, want %T", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package request
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// RequestMiddleware inspects or modifies an outgoing request before it is sent.
+// Returning a non-nil error aborts the call with that middleware named as the cause.
+type RequestMiddleware func(*http.Request) error
+
+// ResponseMiddleware inspects or modifies an incoming response before its body is read.
+// Returning a non-nil error aborts the call with that middleware named as the cause.
+type ResponseMiddleware func(*http.Response) error
+
+// WithRequestMiddleware appends middlewares to the chain run, in registration order,
+// against the outgoing request.
+func WithRequestMiddleware(middlewares ...RequestMiddleware) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.RequestMiddlewares = append(r.RequestMiddlewares, middlewares...)
+	}
+}
+
+// WithResponseMiddleware appends middlewares to the chain run, in registration order,
+// against the incoming response.
+func WithResponseMiddleware(middlewares ...ResponseMiddleware) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.ResponseMiddlewares = append(r.ResponseMiddlewares, middlewares...)
+	}
+}
+
+// recoveryMiddleware is the concrete func value returned by RecoveryMiddleware. Its
+// identity (not its behavior) is what matters: runRequestMiddlewares recognizes it by
+// function pointer and starts recovering panics from everything registered after it.
+func recoveryMiddleware(*http.Request) error { return nil }
+
+// RecoveryMiddleware, once added to a request middleware chain, causes any panic raised
+// by a middleware registered after it to be recovered and reported as an *Error instead
+// of crashing the caller.
+func RecoveryMiddleware() RequestMiddleware {
+	return recoveryMiddleware
+}
+
+// isRecoveryMiddleware reports whether mw is the sentinel returned by RecoveryMiddleware.
+func isRecoveryMiddleware(mw RequestMiddleware) bool {
+	return reflect.ValueOf(mw).Pointer() == reflect.ValueOf(RequestMiddleware(recoveryMiddleware)).Pointer()
+}
+
+// middlewareName derives a short, readable name for a middleware value from its function
+// pointer, for use in error messages naming the failing middleware.
+func middlewareName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] == '.' {
+			return name[i+1:]
+		}
+	}
+	return name
+}
+
+// runRequestMiddlewares runs middlewares against request in order. RecoveryMiddleware
+// marks the point after which panics are recovered rather than left to crash the caller.
+func runRequestMiddlewares(middlewares []RequestMiddleware, request *http.Request) (result *Error) {
+	recovering := false
+	for _, mw := range middlewares {
+		if isRecoveryMiddleware(mw) {
+			recovering = true
+			continue
+		}
+		if err := runRequestMiddleware(mw, request, recovering); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runRequestMiddleware(mw RequestMiddleware, request *http.Request, recovering bool) (result *Error) {
+	if recovering {
+		defer func() {
+			if r := recover(); r != nil {
+				result = &Error{Message: fmt.Sprintf("request middleware %q panicked: %v", middlewareName(mw), r)}
+			}
+		}()
+	}
+	if err := mw(request); err != nil {
+		return &Error{Message: fmt.Sprintf("request middleware %q failed: %v", middlewareName(mw), err)}
+	}
+	return nil
+}
+
+// runResponseMiddlewares runs middlewares against resp in order, stopping at the first error.
+func runResponseMiddlewares(middlewares []ResponseMiddleware, resp *http.Response) *Error {
+	for _, mw := range middlewares {
+		if err := mw(resp); err != nil {
+			return &Error{Message: fmt.Sprintf("response middleware %q failed: %v", middlewareName(mw), err)}
+		}
+	}
+	return nil
+}
+
+// LoggingMiddleware returns a ResponseMiddleware that writes one Apache common-log-format
+// line per request to w.
+func LoggingMiddleware(w io.Writer) ResponseMiddleware {
+	return func(resp *http.Response) error {
+		size := "-"
+		if resp.ContentLength >= 0 {
+			size = strconv.FormatInt(resp.ContentLength, 10)
+		}
+
+		_, err := fmt.Fprintf(w, "%s - - [%s] \"%s %s %s\" %d %s\n",
+			resp.Request.URL.Hostname(),
+			time.Now().Format("02/Jan/2006:15:04:05 -0700"),
+			resp.Request.Method,
+			resp.Request.URL.RequestURI(),
+			resp.Proto,
+			resp.StatusCode,
+			size,
+		)
+		return err
+	}
+}
+
+// UserAgentMiddleware returns a RequestMiddleware that sets the User-Agent header.
+func UserAgentMiddleware(userAgent string) RequestMiddleware {
+	return func(request *http.Request) error {
+		request.Header.Set("User-Agent", userAgent)
+		return nil
+	}
+}
+
+// gzipReadCloser wraps a gzip.Reader together with the underlying response body it
+// reads from, since gzip.Reader.Close does not close the reader it wraps.
+type gzipReadCloser struct {
+	*gzip.Reader
+	source io.Closer
+}
+
+// Close closes both the gzip reader and the underlying response body, so the
+// connection is still returned to the transport's pool.
+func (g *gzipReadCloser) Close() error {
+	gzipErr := g.Reader.Close()
+	sourceErr := g.source.Close()
+	if gzipErr != nil {
+		return gzipErr
+	}
+	return sourceErr
+}
+
+// GzipDecompressMiddleware returns a ResponseMiddleware that transparently wraps the
+// response body in a gzip.Reader when the response is Content-Encoding: gzip.
+func GzipDecompressMiddleware() ResponseMiddleware {
+	return func(resp *http.Response) error {
+		if resp.Header.Get("Content-Encoding") != "gzip" {
+			return nil
+		}
+
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		resp.Body = &gzipReadCloser{Reader: reader, source: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.ContentLength = -1
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgentMiddleware(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	if err := UserAgentMiddleware("test-agent/1.0")(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := request.Header.Get("User-Agent"); got != "test-agent/1.0" {
+		t.Errorf("expected User-Agent to be 'test-agent/1.0', got %s", got)
+	}
+}
+
+func TestRunRequestMiddlewares_RecoversAfterRecoveryMiddleware(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	panicking := RequestMiddleware(func(*http.Request) error {
+		panic("boom")
+	})
+
+	err := runRequestMiddlewares([]RequestMiddleware{RecoveryMiddleware(), panicking}, request)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestRunRequestMiddlewares_ShortCircuitsOnError(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	called := false
+
+	middlewares := []RequestMiddleware{
+		func(*http.Request) error { return &Error{Message: "nope"} },
+		func(*http.Request) error {
+			called = true
+			return nil
+		},
+	}
+
+	if err := runRequestMiddlewares(middlewares, request); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if called {
+		t.Error("expected subsequent middleware not to run after an error")
+	}
+}
+
+// closeTrackingBody wraps a bytes.Reader to observe whether Close was called on it.
+type closeTrackingBody struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (c *closeTrackingBody) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestClient_Middlewares_RunBeforePerCallMiddlewaresInOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var order []string
+
+	client := New(server.URL)
+	client.RequestMiddlewares = []RequestMiddleware{
+		func(*http.Request) error {
+			order = append(order, "client-request")
+			return nil
+		},
+	}
+	client.ResponseMiddlewares = []ResponseMiddleware{
+		func(*http.Response) error {
+			order = append(order, "client-response")
+			return nil
+		},
+	}
+
+	_, err := Get[struct{}]("/get", WithClient(client),
+		WithRequestMiddleware(func(*http.Request) error {
+			order = append(order, "call-request")
+			return nil
+		}),
+		WithResponseMiddleware(func(*http.Response) error {
+			order = append(order, "call-response")
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"client-request", "call-request", "client-response", "call-response"}
+	if len(order) != len(want) {
+		t.Fatalf("expected middleware order %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("expected middleware order %v, got %v", want, order)
+			break
+		}
+	}
+}
+
+func TestGzipDecompressMiddleware_ClosesUnderlyingBody(t *testing.T) {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	if _, err := gzWriter.Write([]byte("payload")); err != nil {
+		t.Fatalf("failed to write gzip payload: %v", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+
+	source := &closeTrackingBody{Reader: bytes.NewReader(buf.Bytes())}
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   source,
+	}
+
+	if err := GzipDecompressMiddleware()(resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read decompressed body: %v", err)
+	}
+	if string(data) != "payload" {
+		t.Errorf("expected 'payload', got %s", data)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("unexpected error closing body: %v", err)
+	}
+
+	if !source.closed {
+		t.Error("expected the underlying response body to be closed")
+	}
+}
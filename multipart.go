@@ -0,0 +1,76 @@
+package request
+
+import (
+	"io"
+	"mime/multipart"
+)
+
+// WithMultipartForm builds a multipart/form-data body from fields and files, streaming
+// it through an io.Pipe as the request reads it rather than buffering it all up front.
+// The resulting body is not seekable, so it cannot be replayed if WithRetry is enabled.
+func WithMultipartForm(fields map[string]string, files map[string]io.Reader) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		pipeReader, pipeWriter := io.Pipe()
+		writer := multipart.NewWriter(pipeWriter)
+
+		go func() {
+			defer pipeWriter.Close()
+
+			for name, value := range fields {
+				if err := writer.WriteField(name, value); err != nil {
+					_ = pipeWriter.CloseWithError(err)
+					return
+				}
+			}
+
+			for name, file := range files {
+				part, err := writer.CreateFormFile(name, name)
+				if err != nil {
+					_ = pipeWriter.CloseWithError(err)
+					return
+				}
+				if _, err := io.Copy(part, file); err != nil {
+					_ = pipeWriter.CloseWithError(err)
+					return
+				}
+			}
+
+			if err := writer.Close(); err != nil {
+				_ = pipeWriter.CloseWithError(err)
+			}
+		}()
+
+		r.BodyReader = pipeReader
+		r.Headers["Content-Type"] = writer.FormDataContentType()
+	}
+}
+
+// WithFile streams a single file as a multipart/form-data body, field name fieldName and
+// filename filename, without buffering r. Like WithMultipartForm, the resulting body is
+// not seekable and cannot be replayed if WithRetry is enabled.
+func WithFile(fieldName, filename string, r io.Reader) func(*RequestConfiguration) {
+	return func(rc *RequestConfiguration) {
+		pipeReader, pipeWriter := io.Pipe()
+		writer := multipart.NewWriter(pipeWriter)
+
+		go func() {
+			defer pipeWriter.Close()
+
+			part, err := writer.CreateFormFile(fieldName, filename)
+			if err != nil {
+				_ = pipeWriter.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(part, r); err != nil {
+				_ = pipeWriter.CloseWithError(err)
+				return
+			}
+			if err := writer.Close(); err != nil {
+				_ = pipeWriter.CloseWithError(err)
+			}
+		}()
+
+		rc.BodyReader = pipeReader
+		rc.Headers["Content-Type"] = writer.FormDataContentType()
+	}
+}
@@ -0,0 +1,101 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMultipartForm(t *testing.T) {
+	requestConfig := &RequestConfiguration{Headers: map[string]string{}}
+	WithMultipartForm(
+		map[string]string{"key": "value"},
+		map[string]io.Reader{"upload": strings.NewReader("file contents")},
+	)(requestConfig)
+
+	_, params, err := mime.ParseMediaType(requestConfig.Headers["Content-Type"])
+	if err != nil {
+		t.Fatalf("failed to parse Content-Type: %v", err)
+	}
+
+	reader := multipart.NewReader(requestConfig.BodyReader, params["boundary"])
+	form, err := reader.ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("failed to read multipart form: %v", err)
+	}
+
+	if got := form.Value["key"]; len(got) != 1 || got[0] != "value" {
+		t.Errorf("expected field key=value, got %v", got)
+	}
+
+	file, err := form.File["upload"][0].Open()
+	if err != nil {
+		t.Fatalf("failed to open uploaded file: %v", err)
+	}
+	defer file.Close()
+
+	contents, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("failed to read uploaded file: %v", err)
+	}
+	if string(contents) != "file contents" {
+		t.Errorf("expected 'file contents', got %s", contents)
+	}
+}
+
+func TestWithFile(t *testing.T) {
+	requestConfig := &RequestConfiguration{Headers: map[string]string{}}
+	WithFile("upload", "report.txt", strings.NewReader("report body"))(requestConfig)
+
+	_, params, err := mime.ParseMediaType(requestConfig.Headers["Content-Type"])
+	if err != nil {
+		t.Fatalf("failed to parse Content-Type: %v", err)
+	}
+
+	reader := multipart.NewReader(requestConfig.BodyReader, params["boundary"])
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("failed to read multipart part: %v", err)
+	}
+
+	if part.FileName() != "report.txt" {
+		t.Errorf("expected filename 'report.txt', got %s", part.FileName())
+	}
+
+	contents, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatalf("failed to read part contents: %v", err)
+	}
+	if string(contents) != "report body" {
+		t.Errorf("expected 'report body', got %s", contents)
+	}
+}
+
+func TestPost_ClosesUploadBodyWhenRequestMiddlewareFails(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	_, err := Post[struct{}]("https://example.com/upload",
+		WithFile("upload", "report.txt", strings.NewReader("report body")),
+		WithRequestMiddleware(func(*http.Request) error {
+			return errors.New("blocked")
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected the upload goroutine to exit, goroutine count went from %d to %d", before, after)
+	}
+}
@@ -2,10 +2,13 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // Method represents an HTTP method.
@@ -22,6 +25,62 @@ const (
 type RequestConfiguration struct {
 	Headers, Parameters, Cookies map[string]string
 	Body                         []byte
+	// BodyReader, when set, is used as the request body instead of Body, so large
+	// uploads (see WithMultipartForm and WithFile) don't have to be fully buffered.
+	// It is only replayable across retries if it also implements io.Seeker.
+	BodyReader          io.Reader
+	client              *Client
+	Encoder             Encoder
+	Decoder             Decoder
+	RequestMiddlewares  []RequestMiddleware
+	ResponseMiddlewares []ResponseMiddleware
+	digestAuth          *digestAuth
+
+	RetryCount     int
+	RetryWaitMin   time.Duration
+	RetryWaitMax   time.Duration
+	RetryBackoff   func(attempt int) time.Duration
+	RetryCondition func(status int, body []byte, err error) bool
+}
+
+// Client holds the shared configuration for a group of requests against the same API:
+// a base URL, the *http.Client used to send them (tune its Timeout, Transport, or
+// CheckRedirect directly), and a set of default options applied by WithClient at its
+// place in a call's option list, so per-call options listed after it override a default.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Defaults   []func(*RequestConfiguration)
+
+	RequestMiddlewares  []RequestMiddleware
+	ResponseMiddlewares []ResponseMiddleware
+
+	digestChallenges sync.Map
+}
+
+// defaultClient backs the package-level Get/Post/Put/Delete functions so they keep
+// working exactly as before: no base URL, and http.DefaultClient underneath.
+var defaultClient = &Client{HTTPClient: http.DefaultClient}
+
+// New creates a Client for the given base URL, using its own *http.Client so callers
+// can tune Timeout, Transport, and CheckRedirect without affecting other clients.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{},
+	}
+}
+
+// WithClient routes a call through the given Client instead of the package default.
+// The Client's Defaults are applied immediately, so WithClient should be listed before
+// any per-call options that are meant to override them.
+func WithClient(client *Client) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.client = client
+		for _, option := range client.Defaults {
+			option(r)
+		}
+	}
 }
 
 // Error represents an error returned by the application.
@@ -29,6 +88,8 @@ type Error struct {
 	Code    int
 	Body    []byte
 	Message string
+	// Attempt is the number of attempts made, including the first. It is 1 unless retries are enabled.
+	Attempt int
 }
 
 // Error returns the error message associated with the Error struct.
@@ -38,7 +99,7 @@ func (e *Error) Error() string {
 
 // do is a generic function that performs an HTTP request with the specified method, URL, and request options.
 // It returns the response body decoded into the type T and an error if the request fails or the response code indicates an error.
-func do[T any](method Method, url string, options ...func(*RequestConfiguration)) (T, *Error) {
+func do[T any](ctx context.Context, method Method, target string, options ...func(*RequestConfiguration)) (T, *Error) {
 	requestConfig := RequestConfiguration{
 		Headers:    make(map[string]string),
 		Parameters: make(map[string]string),
@@ -48,11 +109,139 @@ func do[T any](method Method, url string, options ...func(*RequestConfiguration)
 		option(&requestConfig)
 	}
 
+	client := requestConfig.client
+	if client == nil {
+		client = defaultClient
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	retryCondition := requestConfig.RetryCondition
+	if retryCondition == nil {
+		retryCondition = defaultRetryCondition
+	}
+	retryBackoff := requestConfig.RetryBackoff
+	if retryBackoff == nil {
+		retryBackoff = defaultRetryBackoff(requestConfig.RetryWaitMin, requestConfig.RetryWaitMax)
+	}
+	requestMiddlewares := append(append([]RequestMiddleware{}, client.RequestMiddlewares...), requestConfig.RequestMiddlewares...)
+	responseMiddlewares := append(append([]ResponseMiddleware{}, client.ResponseMiddlewares...), requestConfig.ResponseMiddlewares...)
+
 	var result T
-	client := http.DefaultClient
-	request, err := http.NewRequest(string(method), url, io.NopCloser(bytes.NewReader(requestConfig.Body)))
+	var lastErr *Error
+	var retryAfter time.Duration
+	for attempt := 0; attempt <= requestConfig.RetryCount; attempt++ {
+		if attempt > 0 {
+			if requestConfig.BodyReader != nil {
+				seeker, ok := requestConfig.BodyReader.(io.Seeker)
+				if !ok {
+					return result, &Error{Message: "request body cannot be replayed for retry", Attempt: attempt}
+				}
+				if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+					return result, &Error{Message: fmt.Sprintf("failed to rewind request body for retry: %v", err), Attempt: attempt}
+				}
+			}
+
+			wait := retryAfter
+			if wait == 0 {
+				wait = retryBackoff(attempt - 1)
+			}
+			select {
+			case <-ctx.Done():
+				return result, &Error{Message: "request canceled", Attempt: attempt}
+			case <-time.After(wait):
+			}
+		}
+
+		request, err := newRequest(ctx, method, client.BaseURL+target, &requestConfig)
+		if err != nil {
+			return result, &Error{Message: "failed to create request", Attempt: attempt + 1}
+		}
+
+		if requestConfig.digestAuth != nil {
+			applyCachedDigestAuth(client, request, requestConfig.digestAuth)
+		}
+
+		if err := runRequestMiddlewares(requestMiddlewares, request); err != nil {
+			_ = request.Body.Close()
+			err.Attempt = attempt + 1
+			return result, err
+		}
+
+		resp, err := httpClient.Do(request)
+		if err != nil {
+			lastErr = &Error{Message: "failed to send request", Attempt: attempt + 1}
+			if ctx.Err() != nil || attempt == requestConfig.RetryCount || !retryCondition(0, nil, err) {
+				return result, lastErr
+			}
+			retryAfter = 0
+			continue
+		}
+
+		if requestConfig.digestAuth != nil && isDigestChallenge(resp) {
+			reissued, digestErr := reissueWithDigest(ctx, httpClient, client, method, client.BaseURL+target, &requestConfig, resp.Header.Get("WWW-Authenticate"))
+			_ = resp.Body.Close()
+			if digestErr != nil {
+				return result, &Error{Message: fmt.Sprintf("digest authentication failed: %v", digestErr), Attempt: attempt + 1}
+			}
+			resp = reissued
+		}
+
+		if err := runResponseMiddlewares(responseMiddlewares, resp); err != nil {
+			_ = resp.Body.Close()
+			err.Attempt = attempt + 1
+			return result, err
+		}
+
+		responseBytes, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if !isSuccessfulCode(resp.StatusCode) {
+			lastErr = &Error{
+				Code:    resp.StatusCode,
+				Body:    responseBytes,
+				Message: fmt.Sprintf("status code does not indicate success: %d", resp.StatusCode),
+				Attempt: attempt + 1,
+			}
+			if attempt == requestConfig.RetryCount || !retryCondition(resp.StatusCode, responseBytes, nil) {
+				return result, lastErr
+			}
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			continue
+		}
+
+		decoder := requestConfig.Decoder
+		if decoder == nil {
+			decoder = decoderForContentType(resp.Header.Get("Content-Type"))
+		}
+
+		if err := decoder.Decode(responseBytes, &result); err != nil {
+			if unmarshalErr, ok := err.(*json.UnmarshalTypeError); ok {
+				return result, &Error{Message: fmt.Sprintf("failed to decode field \"%s\"", unmarshalErr.Field), Attempt: attempt + 1}
+			}
+			return result, &Error{Message: "failed to decode response", Attempt: attempt + 1}
+		}
+
+		return result, nil
+	}
+
+	return result, lastErr
+}
+
+// newRequest builds an *http.Request for url carrying requestConfig's body, headers,
+// query parameters, and cookies. It does not apply authentication or middlewares, so it
+// can be reused to build the digest-auth reissue in auth.go identically to the original.
+func newRequest(ctx context.Context, method Method, url string, requestConfig *RequestConfiguration) (*http.Request, error) {
+	var body io.Reader = bytes.NewReader(requestConfig.Body)
+	if requestConfig.BodyReader != nil {
+		body = requestConfig.BodyReader
+	}
+
+	request, err := http.NewRequestWithContext(ctx, string(method), url, body)
 	if err != nil {
-		return result, &Error{Message: "failed to create request"}
+		return nil, err
 	}
 
 	for key, value := range requestConfig.Headers {
@@ -69,55 +258,51 @@ func do[T any](method Method, url string, options ...func(*RequestConfiguration)
 		request.AddCookie(&http.Cookie{Name: key, Value: value})
 	}
 
-	resp, err := client.Do(request)
-	if err != nil {
-		return result, &Error{Message: "failed to send request"}
-	}
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(resp.Body)
-
-	if !isSuccessfulCode(resp.StatusCode) {
-		responseBytes, _ := io.ReadAll(resp.Body)
-		return result, &Error{
-			Code:    resp.StatusCode,
-			Body:    responseBytes,
-			Message: fmt.Sprintf("status code does not indicate success: %d", resp.StatusCode),
-		}
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		if unmarshalErr, ok := err.(*json.UnmarshalTypeError); ok {
-			return result, &Error{Message: fmt.Sprintf("failed to decode field \"%s\"", unmarshalErr.Field)}
-		}
-		return result, &Error{Message: "failed to decode response"}
-	}
-
-	return result, nil
+	return request, nil
 }
 
 // Get is a generic function that performs an HTTP GET request with the specified URL and request options.
 // It returns the response body decoded into the type T and an error if the request fails or the response code indicates an error.
 func Get[T any](url string, options ...func(*RequestConfiguration)) (T, *Error) {
-	return do[T](getMethod, url, options...)
+	return GetCtx[T](context.Background(), url, options...)
+}
+
+// GetCtx is the context-aware variant of Get. The request is aborted as soon as ctx is done.
+func GetCtx[T any](ctx context.Context, url string, options ...func(*RequestConfiguration)) (T, *Error) {
+	return do[T](ctx, getMethod, url, options...)
 }
 
 // Post is a generic function that performs an HTTP GET request with the specified URL and request options.
 // It returns the response body decoded into the type T and an error if the request fails or the response code indicates an error.
 func Post[T any](url string, options ...func(*RequestConfiguration)) (T, *Error) {
-	return do[T](postMethod, url, options...)
+	return PostCtx[T](context.Background(), url, options...)
+}
+
+// PostCtx is the context-aware variant of Post. The request is aborted as soon as ctx is done.
+func PostCtx[T any](ctx context.Context, url string, options ...func(*RequestConfiguration)) (T, *Error) {
+	return do[T](ctx, postMethod, url, options...)
 }
 
 // Put is a generic function that performs an HTTP GET request with the specified URL and request options.
 // It returns the response body decoded into the type T and an error if the request fails or the response code indicates an error.
 func Put[T any](url string, options ...func(*RequestConfiguration)) (T, *Error) {
-	return do[T](putMethod, url, options...)
+	return PutCtx[T](context.Background(), url, options...)
+}
+
+// PutCtx is the context-aware variant of Put. The request is aborted as soon as ctx is done.
+func PutCtx[T any](ctx context.Context, url string, options ...func(*RequestConfiguration)) (T, *Error) {
+	return do[T](ctx, putMethod, url, options...)
 }
 
 // Delete is a generic function that performs an HTTP GET request with the specified URL and request options.
 // It returns the response body decoded into the type T and an error if the request fails or the response code indicates an error.
 func Delete[T any](url string, options ...func(*RequestConfiguration)) (T, *Error) {
-	return do[T](deleteMethod, url, options...)
+	return DeleteCtx[T](context.Background(), url, options...)
+}
+
+// DeleteCtx is the context-aware variant of Delete. The request is aborted as soon as ctx is done.
+func DeleteCtx[T any](ctx context.Context, url string, options ...func(*RequestConfiguration)) (T, *Error) {
+	return do[T](ctx, deleteMethod, url, options...)
 }
 
 // isSuccessfulCode checks if the given code falls within the range of successful HTTP status codes.
@@ -155,18 +340,25 @@ func WithAccept() func(*RequestConfiguration) {
 }
 
 // WithBody is a higher-order function that takes a value of any type as its argument and returns
-// a function that takes a pointer to a RequestConfiguration object as its argument. The returned function sets
-// the Body field of the provided RequestConfiguration object with the JSON representation of the input value.
-// If the marshaling of the input value fails, the Body field will remain unchanged.
+// a function that takes a pointer to a RequestConfiguration object as its argument. The returned function
+// encodes the input value with the configured Encoder (JSONCodec unless WithEncoder pinned one first) and
+// sets it as the Body field of the provided RequestConfiguration object.
+// If encoding the input value fails, the Body field will remain unchanged.
 func WithBody[T any](body T) func(*RequestConfiguration) {
 	return func(r *RequestConfiguration) {
-		jsonBody, err := json.Marshal(body)
+		encoder := r.Encoder
+		if encoder == nil {
+			encoder = JSONCodec{}
+		}
+
+		data, err := encoder.Encode(body)
 		if err != nil {
 			return
 		}
 
-		r.Body = jsonBody
-		r.Headers["Content-Type"] = "application/json"
+		r.Body = data
+		r.Encoder = encoder
+		r.Headers["Content-Type"] = encoder.ContentType()
 	}
 }
 
@@ -1,6 +1,11 @@
 package request
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 type getJsonResponse struct {
 	Slideshow struct {
@@ -70,3 +75,53 @@ func TestPost(t *testing.T) {
 		t.Errorf("expected status code 404, got %d", err.Code)
 	}
 }
+
+func TestClient_Get(t *testing.T) {
+	client := New("https://httpbin.org")
+
+	response, err := Get[getResponse]("/get", WithClient(client), WithParameter("key", "value"))
+	if err != nil {
+		t.Errorf("failed to get JSON: %v", err)
+	}
+
+	if response.Args["key"] != "value" {
+		t.Errorf("expected key to be 'value', got %s", response.Args["key"])
+	}
+}
+
+func TestClient_Defaults_ApplyAndCanBeOverridden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"args":{"key":"` + r.URL.Query().Get("key") + `"}}`))
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+	client.Defaults = []func(*RequestConfiguration){WithParameter("key", "default")}
+
+	response, err := Get[getResponse]("/get", WithClient(client))
+	if err != nil {
+		t.Fatalf("failed to get JSON: %v", err)
+	}
+	if response.Args["key"] != "default" {
+		t.Errorf("expected default to apply, got %s", response.Args["key"])
+	}
+
+	response, err = Get[getResponse]("/get", WithClient(client), WithParameter("key", "override"))
+	if err != nil {
+		t.Fatalf("failed to get JSON: %v", err)
+	}
+	if response.Args["key"] != "override" {
+		t.Errorf("expected per-call option to override the default, got %s", response.Args["key"])
+	}
+}
+
+func TestGetCtx_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GetCtx[getResponse](ctx, "https://httpbin.org/get")
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+package request
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultRetryWaitMin and defaultRetryWaitMax bound the backoff used when WithRetry is
+// enabled without an explicit WithRetryWaitTime.
+const (
+	defaultRetryWaitMin = 100 * time.Millisecond
+	defaultRetryWaitMax = 2 * time.Second
+)
+
+// WithRetry enables automatic retries, attempting the request up to count additional
+// times after the first attempt whenever the retry condition matches.
+func WithRetry(count int) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.RetryCount = count
+	}
+}
+
+// WithRetryWaitTime sets the base and the cap of the default backoff: before jitter, the
+// wait ahead of retry n (zero-based) is min(max, min*2^n), and the jittered wait is drawn
+// from [0, that value), so it can be shorter than min. It has no effect on a backoff set
+// with WithRetryBackoff.
+func WithRetryWaitTime(min, max time.Duration) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.RetryWaitMin = min
+		r.RetryWaitMax = max
+	}
+}
+
+// WithRetryBackoff overrides the default exponential-backoff-with-jitter formula. It is
+// called with the zero-based index of the retry about to be made (0 for the first retry).
+func WithRetryBackoff(backoff func(attempt int) time.Duration) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.RetryBackoff = backoff
+	}
+}
+
+// WithRetryCondition overrides the default decision of whether a response or error is
+// retryable. err is non-nil only when the request itself failed to complete, in which
+// case status is 0 and body is nil.
+func WithRetryCondition(condition func(status int, body []byte, err error) bool) func(*RequestConfiguration) {
+	return func(r *RequestConfiguration) {
+		r.RetryCondition = condition
+	}
+}
+
+// defaultRetryCondition retries network errors and 429/5xx responses.
+func defaultRetryCondition(status int, _ []byte, err error) bool {
+	if err != nil {
+		return true
+	}
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// defaultRetryBackoff returns a backoff function that sleeps min(max, waitMin*2^attempt)
+// with full jitter, as recommended by AWS's exponential backoff and jitter guidance.
+func defaultRetryBackoff(waitMin, waitMax time.Duration) func(attempt int) time.Duration {
+	if waitMin <= 0 {
+		waitMin = defaultRetryWaitMin
+	}
+	if waitMax <= 0 {
+		waitMax = defaultRetryWaitMax
+	}
+
+	return func(attempt int) time.Duration {
+		capped := waitMin << attempt
+		if capped <= 0 || capped > waitMax {
+			capped = waitMax
+		}
+		if capped <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(capped)))
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value in either the delay-seconds or
+// HTTP-date form and returns the remaining wait. It returns 0 if value is empty or
+// cannot be parsed.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
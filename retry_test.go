@@ -0,0 +1,37 @@
+package request
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGet_Retry(t *testing.T) {
+	_, err := Get[struct{}]("https://httpbin.org/status/500",
+		WithRetry(2),
+		WithRetryWaitTime(time.Millisecond, 5*time.Millisecond),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if err.Attempt != 3 {
+		t.Errorf("expected 3 attempts, got %d", err.Attempt)
+	}
+}
+
+func TestGet_RetryCondition(t *testing.T) {
+	_, err := Get[struct{}]("https://httpbin.org/status/500",
+		WithRetry(2),
+		WithRetryWaitTime(time.Millisecond, 5*time.Millisecond),
+		WithRetryCondition(func(status int, _ []byte, _ error) bool {
+			return false
+		}),
+	)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if err.Attempt != 1 {
+		t.Errorf("expected 1 attempt when retry condition always rejects, got %d", err.Attempt)
+	}
+}